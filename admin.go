@@ -0,0 +1,447 @@
+package main
+
+import (
+	"net/http"
+	"strconv"
+
+	"rnd-surajan-cognito-go/auth"
+
+	"github.com/aws/aws-sdk-go/aws"
+	cognito "github.com/aws/aws-sdk-go/service/cognitoidentityprovider"
+	"github.com/gin-gonic/gin"
+)
+
+// adminGroupName is the Cognito group membership required to reach any
+// route under /admin.
+const adminGroupName = "cognito-admin"
+
+type (
+	// Admin Create User
+	AdminCreateUserRequest struct {
+		Username          string            `json:"username" binding:"required"`
+		TemporaryPassword string            `json:"temporaryPassword"`
+		UserAttributes    map[string]string `json:"userAttributes"`
+		// MessageAction is "SUPPRESS" to not send the welcome message, or
+		// "RESEND" to resend it to an existing, unconfirmed user. Leave
+		// empty for Cognito's default behaviour.
+		MessageAction string `json:"messageAction"`
+	}
+
+	// Admin Set User Password
+	AdminSetUserPasswordRequest struct {
+		Password  string `json:"password" binding:"required"`
+		Permanent bool   `json:"permanent"`
+	}
+
+	// Admin Update User Attributes
+	AdminUpdateUserAttributesRequest struct {
+		UserAttributes map[string]string `json:"userAttributes" binding:"required"`
+	}
+
+	// Group Create
+	GroupRequest struct {
+		GroupName   string `json:"groupName" binding:"required"`
+		Description string `json:"description"`
+		Precedence  *int64 `json:"precedence"`
+	}
+)
+
+// attributesFromMap converts the flat attribute payload admin requests
+// accept over JSON into the []*cognito.AttributeType shape the SDK wants.
+func attributesFromMap(attrs map[string]string) []*cognito.AttributeType {
+	attributes := make([]*cognito.AttributeType, 0, len(attrs))
+	for name, value := range attrs {
+		attributes = append(attributes, &cognito.AttributeType{
+			Name:  aws.String(name),
+			Value: aws.String(value),
+		})
+	}
+	return attributes
+}
+
+// RequireGroup checks that the token's claims list the given Cognito
+// group. It does no JWT verification itself: it must be chained after
+// app.RequireAuth so Claims are already on the context.
+func (app *App) RequireGroup(group string) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		claims, ok := auth.ClaimsFromContext(ctx)
+		if !ok {
+			ctx.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{
+				"error": "missing claims; is this route behind RequireAuth?",
+			})
+			return
+		}
+
+		for _, g := range claims.Groups {
+			if g == group {
+				ctx.Next()
+				return
+			}
+		}
+
+		ctx.AbortWithStatusJSON(http.StatusForbidden, gin.H{
+			"error": "requires membership in the " + group + " group",
+		})
+	}
+}
+
+// RegisterAdminRoutes wires the admin-scoped user and group management
+// API, protected by a valid access token plus membership in the
+// adminGroupName Cognito group.
+func (app *App) RegisterAdminRoutes(r *gin.Engine) {
+	admin := r.Group("/admin", app.RequireAuth(auth.TokenUseAccess), app.RequireGroup(adminGroupName))
+
+	admin.GET("/users", app.ListUsers)
+	admin.GET("/users/:username", app.AdminGetUser)
+	admin.POST("/users", app.AdminCreateUser)
+	admin.DELETE("/users/:username", app.AdminDeleteUser)
+	admin.POST("/users/:username/disable", app.AdminDisableUser)
+	admin.POST("/users/:username/enable", app.AdminEnableUser)
+	admin.POST("/users/:username/password", app.AdminSetUserPassword)
+	admin.PATCH("/users/:username/attributes", app.AdminUpdateUserAttributes)
+	admin.POST("/users/:username/groups/:group", app.AdminAddUserToGroup)
+	admin.DELETE("/users/:username/groups/:group", app.AdminRemoveUserFromGroup)
+
+	admin.POST("/groups", app.CreateGroup)
+	admin.GET("/groups", app.ListGroups)
+	admin.DELETE("/groups/:group", app.DeleteGroup)
+}
+
+// ListUsers returns a page of users in the pool, optionally narrowed by a
+// Cognito filter expression (e.g. `email ^= "name"`). Pagination follows
+// Cognito's own token-based scheme: pass the previous response's
+// paginationToken back in the ?paginationToken= query param to get the
+// next page.
+func (app *App) ListUsers(ctx *gin.Context) {
+	input := &cognito.ListUsersInput{
+		UserPoolId: aws.String(app.UserPoolID),
+	}
+	if limit := ctx.Query("limit"); limit != "" {
+		parsed, err := strconv.ParseInt(limit, 10, 64)
+		if err != nil {
+			ctx.JSON(http.StatusBadRequest, gin.H{
+				"error": "limit must be an integer",
+			})
+			return
+		}
+		input.Limit = aws.Int64(parsed)
+	}
+	if filter := ctx.Query("filter"); filter != "" {
+		input.Filter = aws.String(filter)
+	}
+	if paginationToken := ctx.Query("paginationToken"); paginationToken != "" {
+		input.PaginationToken = aws.String(paginationToken)
+	}
+
+	result, err := app.CognitoClient.ListUsers(input)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+
+	response := gin.H{"users": result.Users}
+	if result.PaginationToken != nil {
+		response["paginationToken"] = *result.PaginationToken
+	}
+
+	// OK
+	ctx.JSON(http.StatusOK, response)
+}
+
+// AdminGetUser returns a single user's full record, including fields such
+// as creation dates and MFA settings not exposed by the self-service /me
+// route.
+func (app *App) AdminGetUser(ctx *gin.Context) {
+	result, err := app.CognitoClient.AdminGetUser(&cognito.AdminGetUserInput{
+		UserPoolId: aws.String(app.UserPoolID),
+		Username:   aws.String(ctx.Param("username")),
+	})
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+
+	// OK
+	ctx.JSON(http.StatusOK, result)
+}
+
+// AdminCreateUser provisions a new user out-of-band from self-service
+// signup, e.g. for back-office or invite-only onboarding.
+func (app *App) AdminCreateUser(ctx *gin.Context) {
+	var newUser AdminCreateUserRequest
+
+	// Validate payload
+	if err := ctx.BindJSON(&newUser); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+
+	input := &cognito.AdminCreateUserInput{
+		UserPoolId:     aws.String(app.UserPoolID),
+		Username:       aws.String(newUser.Username),
+		UserAttributes: attributesFromMap(newUser.UserAttributes),
+	}
+	if newUser.TemporaryPassword != "" {
+		input.TemporaryPassword = aws.String(newUser.TemporaryPassword)
+	}
+	if newUser.MessageAction != "" {
+		input.MessageAction = aws.String(newUser.MessageAction)
+	}
+
+	result, err := app.CognitoClient.AdminCreateUser(input)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+
+	// OK
+	ctx.JSON(http.StatusOK, gin.H{
+		"user": result.User,
+	})
+}
+
+// AdminDeleteUser permanently removes a user from the pool.
+func (app *App) AdminDeleteUser(ctx *gin.Context) {
+	_, err := app.CognitoClient.AdminDeleteUser(&cognito.AdminDeleteUserInput{
+		UserPoolId: aws.String(app.UserPoolID),
+		Username:   aws.String(ctx.Param("username")),
+	})
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+
+	// OK
+	ctx.JSON(http.StatusOK, gin.H{
+		"message": "User deleted successfully",
+	})
+}
+
+// AdminDisableUser prevents a user from signing in without deleting them.
+func (app *App) AdminDisableUser(ctx *gin.Context) {
+	_, err := app.CognitoClient.AdminDisableUser(&cognito.AdminDisableUserInput{
+		UserPoolId: aws.String(app.UserPoolID),
+		Username:   aws.String(ctx.Param("username")),
+	})
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+
+	// OK
+	ctx.JSON(http.StatusOK, gin.H{
+		"message": "User disabled successfully",
+	})
+}
+
+// AdminEnableUser re-allows a user disabled by AdminDisableUser to sign in.
+func (app *App) AdminEnableUser(ctx *gin.Context) {
+	_, err := app.CognitoClient.AdminEnableUser(&cognito.AdminEnableUserInput{
+		UserPoolId: aws.String(app.UserPoolID),
+		Username:   aws.String(ctx.Param("username")),
+	})
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+
+	// OK
+	ctx.JSON(http.StatusOK, gin.H{
+		"message": "User enabled successfully",
+	})
+}
+
+// AdminSetUserPassword sets a user's password directly, bypassing the
+// forgot-password email flow. Set permanent=false to force the user to
+// change it on next sign-in, matching a temporary password.
+func (app *App) AdminSetUserPassword(ctx *gin.Context) {
+	var password AdminSetUserPasswordRequest
+
+	// Validate payload
+	if err := ctx.BindJSON(&password); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+
+	_, err := app.CognitoClient.AdminSetUserPassword(&cognito.AdminSetUserPasswordInput{
+		UserPoolId: aws.String(app.UserPoolID),
+		Username:   aws.String(ctx.Param("username")),
+		Password:   aws.String(password.Password),
+		Permanent:  aws.Bool(password.Permanent),
+	})
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+
+	// OK
+	ctx.JSON(http.StatusOK, gin.H{
+		"message": "Password set successfully",
+	})
+}
+
+// AdminUpdateUserAttributes overwrites the given attributes on a user,
+// e.g. to correct a typo'd email or update a custom attribute from a
+// back-office tool.
+func (app *App) AdminUpdateUserAttributes(ctx *gin.Context) {
+	var update AdminUpdateUserAttributesRequest
+
+	// Validate payload
+	if err := ctx.BindJSON(&update); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+
+	_, err := app.CognitoClient.AdminUpdateUserAttributes(&cognito.AdminUpdateUserAttributesInput{
+		UserPoolId:     aws.String(app.UserPoolID),
+		Username:       aws.String(ctx.Param("username")),
+		UserAttributes: attributesFromMap(update.UserAttributes),
+	})
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+
+	// OK
+	ctx.JSON(http.StatusOK, gin.H{
+		"message": "User attributes updated successfully",
+	})
+}
+
+// AdminAddUserToGroup adds a user to a pool group, e.g. to grant them the
+// adminGroupName group itself.
+func (app *App) AdminAddUserToGroup(ctx *gin.Context) {
+	_, err := app.CognitoClient.AdminAddUserToGroup(&cognito.AdminAddUserToGroupInput{
+		UserPoolId: aws.String(app.UserPoolID),
+		Username:   aws.String(ctx.Param("username")),
+		GroupName:  aws.String(ctx.Param("group")),
+	})
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+
+	// OK
+	ctx.JSON(http.StatusOK, gin.H{
+		"message": "User added to group successfully",
+	})
+}
+
+// AdminRemoveUserFromGroup removes a user from a pool group.
+func (app *App) AdminRemoveUserFromGroup(ctx *gin.Context) {
+	_, err := app.CognitoClient.AdminRemoveUserFromGroup(&cognito.AdminRemoveUserFromGroupInput{
+		UserPoolId: aws.String(app.UserPoolID),
+		Username:   aws.String(ctx.Param("username")),
+		GroupName:  aws.String(ctx.Param("group")),
+	})
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+
+	// OK
+	ctx.JSON(http.StatusOK, gin.H{
+		"message": "User removed from group successfully",
+	})
+}
+
+// CreateGroup creates a new pool group, e.g. adminGroupName itself.
+func (app *App) CreateGroup(ctx *gin.Context) {
+	var group GroupRequest
+
+	// Validate payload
+	if err := ctx.BindJSON(&group); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+
+	input := &cognito.CreateGroupInput{
+		UserPoolId: aws.String(app.UserPoolID),
+		GroupName:  aws.String(group.GroupName),
+	}
+	if group.Description != "" {
+		input.Description = aws.String(group.Description)
+	}
+	if group.Precedence != nil {
+		input.Precedence = group.Precedence
+	}
+
+	result, err := app.CognitoClient.CreateGroup(input)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+
+	// OK
+	ctx.JSON(http.StatusOK, gin.H{
+		"group": result.Group,
+	})
+}
+
+// ListGroups returns every group configured on the pool.
+func (app *App) ListGroups(ctx *gin.Context) {
+	result, err := app.CognitoClient.ListGroups(&cognito.ListGroupsInput{
+		UserPoolId: aws.String(app.UserPoolID),
+	})
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+
+	// OK
+	ctx.JSON(http.StatusOK, gin.H{
+		"groups": result.Groups,
+	})
+}
+
+// DeleteGroup removes a pool group. Users in the group are not deleted,
+// just removed from it.
+func (app *App) DeleteGroup(ctx *gin.Context) {
+	_, err := app.CognitoClient.DeleteGroup(&cognito.DeleteGroupInput{
+		UserPoolId: aws.String(app.UserPoolID),
+		GroupName:  aws.String(ctx.Param("group")),
+	})
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+
+	// OK
+	ctx.JSON(http.StatusOK, gin.H{
+		"message": "Group deleted successfully",
+	})
+}
@@ -0,0 +1,163 @@
+// Package auth validates JWTs issued by a Cognito User Pool without a
+// round-trip to Cognito on every request: the pool's JWKS is fetched once,
+// cached in memory, and refreshed periodically (or on-demand when a token
+// references a `kid` we haven't seen yet, e.g. right after a key rotation).
+package auth
+
+import (
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// defaultRefreshInterval is how long fetched keys are trusted before the
+// next lookup re-fetches the JWKS, independent of lazy re-fetches
+// triggered by an unknown kid. No background goroutine is involved.
+const defaultRefreshInterval = 1 * time.Hour
+
+// jwk is a single entry of a JSON Web Key Set as returned by Cognito's
+// `.well-known/jwks.json` endpoint. We only need the fields required to
+// reconstruct an RSA public key.
+type jwk struct {
+	Kid string `json:"kid"`
+	Kty string `json:"kty"`
+	Alg string `json:"alg"`
+	Use string `json:"use"`
+	N   string `json:"n"`
+	E   string `json:"e"`
+}
+
+type jwksResponse struct {
+	Keys []jwk `json:"keys"`
+}
+
+// JWKSCache fetches and caches the signing keys for a Cognito User Pool.
+// It is safe for concurrent use.
+type JWKSCache struct {
+	// URL is the `.well-known/jwks.json` endpoint for the user pool, e.g.
+	// https://cognito-idp.<region>.amazonaws.com/<userPoolId>/.well-known/jwks.json
+	URL string
+
+	// RefreshInterval is the maximum age of the cached keys; the first
+	// lookup after it has elapsed re-fetches the JWKS. Defaults to
+	// defaultRefreshInterval when zero.
+	RefreshInterval time.Duration
+
+	httpClient *http.Client
+
+	mu        sync.RWMutex
+	keys      map[string]*rsa.PublicKey
+	fetchedAt time.Time
+}
+
+// NewJWKSCache builds a cache for the given JWKS URL. It does not fetch
+// keys eagerly; the first call to Key() will populate the cache.
+func NewJWKSCache(url string) *JWKSCache {
+	return &JWKSCache{
+		URL:             url,
+		RefreshInterval: defaultRefreshInterval,
+		httpClient:      &http.Client{Timeout: 10 * time.Second},
+	}
+}
+
+// Key returns the RSA public key for the given kid, fetching or refreshing
+// the JWKS as needed. If the kid is still unknown after a refresh, an error
+// is returned.
+func (c *JWKSCache) Key(kid string) (*rsa.PublicKey, error) {
+	if key, ok := c.cachedKey(kid); ok {
+		return key, nil
+	}
+
+	// Unknown kid or stale cache: (re)fetch and try once more. This covers
+	// both the cold-start case and Cognito rotating its signing keys.
+	if err := c.refresh(); err != nil {
+		return nil, err
+	}
+
+	if key, ok := c.cachedKey(kid); ok {
+		return key, nil
+	}
+
+	return nil, fmt.Errorf("auth: no JWK found for kid %q", kid)
+}
+
+func (c *JWKSCache) cachedKey(kid string) (*rsa.PublicKey, bool) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	if c.keys == nil {
+		return nil, false
+	}
+	if time.Since(c.fetchedAt) > c.refreshInterval() {
+		return nil, false
+	}
+	key, ok := c.keys[kid]
+	return key, ok
+}
+
+func (c *JWKSCache) refreshInterval() time.Duration {
+	if c.RefreshInterval <= 0 {
+		return defaultRefreshInterval
+	}
+	return c.RefreshInterval
+}
+
+// refresh fetches the JWKS and replaces the cached key set.
+func (c *JWKSCache) refresh() error {
+	resp, err := c.httpClient.Get(c.URL)
+	if err != nil {
+		return fmt.Errorf("auth: fetching JWKS: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("auth: fetching JWKS: unexpected status %d", resp.StatusCode)
+	}
+
+	var parsed jwksResponse
+	if err := json.NewDecoder(resp.Body).Decode(&parsed); err != nil {
+		return fmt.Errorf("auth: decoding JWKS: %w", err)
+	}
+
+	keys := make(map[string]*rsa.PublicKey, len(parsed.Keys))
+	for _, k := range parsed.Keys {
+		if k.Kty != "RSA" {
+			continue
+		}
+		pub, err := jwkToRSAPublicKey(k)
+		if err != nil {
+			continue
+		}
+		keys[k.Kid] = pub
+	}
+
+	c.mu.Lock()
+	c.keys = keys
+	c.fetchedAt = time.Now()
+	c.mu.Unlock()
+
+	return nil
+}
+
+// jwkToRSAPublicKey reconstructs an *rsa.PublicKey from a JWK's base64url
+// encoded modulus (n) and exponent (e).
+func jwkToRSAPublicKey(k jwk) (*rsa.PublicKey, error) {
+	nBytes, err := base64.RawURLEncoding.DecodeString(k.N)
+	if err != nil {
+		return nil, fmt.Errorf("auth: decoding modulus: %w", err)
+	}
+	eBytes, err := base64.RawURLEncoding.DecodeString(k.E)
+	if err != nil {
+		return nil, fmt.Errorf("auth: decoding exponent: %w", err)
+	}
+
+	return &rsa.PublicKey{
+		N: new(big.Int).SetBytes(nBytes),
+		E: int(new(big.Int).SetBytes(eBytes).Int64()),
+	}, nil
+}
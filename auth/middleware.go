@@ -0,0 +1,179 @@
+package auth
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// contextKey is an unexported type for the keys we stash on gin.Context.
+// gin.Context stores them under their plain string value, so the type
+// alone does not keep them apart from keys set by other packages.
+type contextKey string
+
+// ClaimsContextKey is the key under which the verified Claims are stored
+// on the gin.Context by Middleware.
+const ClaimsContextKey contextKey = "auth.claims"
+
+// Claims holds the subset of a Cognito token's claims that downstream
+// handlers typically need. Other claims in the token are not carried over.
+type Claims struct {
+	Sub      string   `json:"sub"`
+	Username string   `json:"username"`
+	Groups   []string `json:"cognito:groups"`
+	Scope    string   `json:"scope"`
+	TokenUse string   `json:"token_use"`
+}
+
+// TokenUse selects whether Middleware accepts Cognito access tokens, ID
+// tokens, or either.
+type TokenUse string
+
+const (
+	TokenUseAccess TokenUse = "access"
+	TokenUseID     TokenUse = "id"
+	TokenUseEither TokenUse = ""
+)
+
+// Config controls how Middleware verifies a token.
+type Config struct {
+	// JWKS is the cache used to look up the signing key for a token's kid.
+	JWKS *JWKSCache
+
+	// Issuer is the expected `iss` claim, i.e.
+	// https://cognito-idp.<region>.amazonaws.com/<userPoolId>
+	Issuer string
+
+	// AppClientID is checked against `aud` (ID tokens) or `client_id`
+	// (access tokens).
+	AppClientID string
+
+	// AllowedTokenUse restricts which `token_use` values are accepted.
+	// Defaults to TokenUseEither (both "access" and "id") when empty.
+	AllowedTokenUse TokenUse
+}
+
+// Middleware returns a gin.HandlerFunc that verifies the bearer token in
+// the Authorization header against Cognito's JWKS and, on success, attaches
+// the parsed Claims to the request context under ClaimsContextKey.
+func Middleware(cfg Config) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		rawToken, err := bearerToken(ctx.GetHeader("Authorization"))
+		if err != nil {
+			ctx.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
+			return
+		}
+
+		claims, err := VerifyToken(rawToken, cfg)
+		if err != nil {
+			ctx.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
+			return
+		}
+
+		ctx.Set(string(ClaimsContextKey), claims)
+		ctx.Next()
+	}
+}
+
+// ClaimsFromContext retrieves the Claims attached by Middleware. The
+// second return value is false if Middleware was not run on this route.
+func ClaimsFromContext(ctx *gin.Context) (Claims, bool) {
+	value, exists := ctx.Get(string(ClaimsContextKey))
+	if !exists {
+		return Claims{}, false
+	}
+	claims, ok := value.(Claims)
+	return claims, ok
+}
+
+func bearerToken(header string) (string, error) {
+	const prefix = "Bearer "
+	if !strings.HasPrefix(header, prefix) {
+		return "", fmt.Errorf("auth: missing or malformed Authorization header")
+	}
+	token := strings.TrimSpace(strings.TrimPrefix(header, prefix))
+	if token == "" {
+		return "", fmt.Errorf("auth: missing or malformed Authorization header")
+	}
+	return token, nil
+}
+
+// VerifyToken validates rawToken against the JWKS and rules in cfg and
+// returns its Claims. It is the same verification Middleware performs,
+// exposed directly for callers that need to validate a token outside of a
+// request's Authorization header (e.g. a /token/introspect endpoint).
+func VerifyToken(rawToken string, cfg Config) (Claims, error) {
+	mapClaims := jwt.MapClaims{}
+
+	_, err := jwt.ParseWithClaims(rawToken, mapClaims, func(token *jwt.Token) (interface{}, error) {
+		if token.Method.Alg() != "RS256" {
+			return nil, fmt.Errorf("auth: unexpected signing method %q", token.Method.Alg())
+		}
+		kid, ok := token.Header["kid"].(string)
+		if !ok {
+			return nil, fmt.Errorf("auth: token is missing kid header")
+		}
+		return cfg.JWKS.Key(kid)
+	}, jwt.WithValidMethods([]string{"RS256"}), jwt.WithIssuer(cfg.Issuer))
+	if err != nil {
+		return Claims{}, fmt.Errorf("auth: invalid token: %w", err)
+	}
+
+	tokenUse, _ := mapClaims["token_use"].(string)
+	if tokenUse != string(TokenUseAccess) && tokenUse != string(TokenUseID) {
+		return Claims{}, fmt.Errorf("auth: unexpected token_use %q", tokenUse)
+	}
+	if cfg.AllowedTokenUse != TokenUseEither && tokenUse != string(cfg.AllowedTokenUse) {
+		return Claims{}, fmt.Errorf("auth: unexpected token_use %q", tokenUse)
+	}
+
+	if err := checkAudience(mapClaims, tokenUse, cfg.AppClientID); err != nil {
+		return Claims{}, err
+	}
+
+	return claimsFromMap(mapClaims, tokenUse), nil
+}
+
+// checkAudience verifies the client identifies the expected app client.
+// ID tokens carry it in `aud`; access tokens carry it in `client_id`.
+func checkAudience(claims jwt.MapClaims, tokenUse, appClientID string) error {
+	var clientID string
+	if tokenUse == string(TokenUseAccess) {
+		clientID, _ = claims["client_id"].(string)
+	} else {
+		clientID, _ = claims["aud"].(string)
+	}
+
+	if clientID != appClientID {
+		return fmt.Errorf("auth: token is not intended for this app client")
+	}
+	return nil
+}
+
+func claimsFromMap(m jwt.MapClaims, tokenUse string) Claims {
+	claims := Claims{
+		TokenUse: tokenUse,
+	}
+	if sub, ok := m["sub"].(string); ok {
+		claims.Sub = sub
+	}
+	if username, ok := m["username"].(string); ok {
+		claims.Username = username
+	} else if username, ok := m["cognito:username"].(string); ok {
+		claims.Username = username
+	}
+	if scope, ok := m["scope"].(string); ok {
+		claims.Scope = scope
+	}
+	if groups, ok := m["cognito:groups"].([]interface{}); ok {
+		for _, g := range groups {
+			if group, ok := g.(string); ok {
+				claims.Groups = append(claims.Groups, group)
+			}
+		}
+	}
+	return claims
+}
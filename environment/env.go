@@ -26,3 +26,26 @@ func GetCognitoAppClientId() string {
 func GetCognitoAppClientSecret() string {
 	return os.Getenv("COGNITO_APP_CLIENT_SECRET")
 }
+
+// GetAWSRegion returns the AWS region the Cognito User Pool lives in.
+// Falls back to "ap-south-1" so existing deployments that never set this
+// variable keep working.
+func GetAWSRegion() string {
+	if region := os.Getenv("AWS_REGION"); region != "" {
+		return region
+	}
+	return "ap-south-1"
+}
+
+// GetCognitoDomain returns the Hosted UI domain configured for the app
+// client, e.g. "my-app.auth.ap-south-1.amazoncognito.com".
+func GetCognitoDomain() string {
+	return os.Getenv("COGNITO_DOMAIN")
+}
+
+// GetCognitoRedirectURI returns the callback URL Cognito should send the
+// Hosted UI authorization code to, which must also be registered as an
+// allowed callback URL on the app client.
+func GetCognitoRedirectURI() string {
+	return os.Getenv("COGNITO_REDIRECT_URI")
+}
@@ -1,8 +1,21 @@
 package main
 
 import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io"
 	"net/http"
+	"net/url"
+	"rnd-surajan-cognito-go/auth"
 	"rnd-surajan-cognito-go/environment"
+	"rnd-surajan-cognito-go/triggers"
+	"strings"
+	"sync"
+	"time"
 
 	"github.com/aws/aws-sdk-go/aws"
 	"github.com/aws/aws-sdk-go/aws/session"
@@ -19,6 +32,34 @@ type (
 		AppClientID     string
 		AppClientSecret string
 		Token           string
+
+		// Region is the AWS region the User Pool lives in, used to build
+		// the JWKS and issuer URLs for token verification.
+		Region string
+
+		// JWKS caches the User Pool's signing keys so bearer tokens can be
+		// verified locally, without a round-trip to Cognito. See the auth
+		// package for details.
+		JWKS *auth.JWKSCache
+
+		// Dispatcher routes Cognito Lambda trigger events (PreSignUp,
+		// PostConfirmation, etc.) to registered handlers. See the triggers
+		// package for details.
+		Dispatcher *triggers.Dispatcher
+
+		// Domain is the Hosted UI domain used for the OAuth2
+		// authorization-code flow, e.g. "my-app.auth.ap-south-1.amazoncognito.com".
+		Domain string
+
+		// RedirectURI is the callback URL Cognito redirects back to after
+		// the Hosted UI authorization-code flow, also registered as an
+		// allowed callback URL on the app client.
+		RedirectURI string
+
+		// pendingOAuth tracks the PKCE code_verifier for each in-flight
+		// /oauth/login → /oauth/callback round trip, keyed by state. Entries
+		// are removed once consumed by the callback.
+		pendingOAuth *oauthStateStore
 	}
 	User struct {
 		// Username is the username decided by the user
@@ -47,6 +88,11 @@ type (
 		ConfirmationCode string `json:"confirmationCode" binding:"required"`
 	}
 
+	// User Resend Confirmation Code
+	UserResendConfirmationCode struct {
+		Username string `json:"username" binding:"required"`
+	}
+
 	// User Forgot Password
 	UserForgotPassword struct {
 		Username string `json:"username" binding:"required"`
@@ -62,13 +108,140 @@ type (
 	UserSignOut struct {
 		AccessToken string `json:"accessToken" binding:"required"`
 	}
+
+	// Refresh Token
+	TokenRefresh struct {
+		// Username is only required when the app client has a secret
+		// configured, since it's needed to compute SECRET_HASH.
+		Username     string `json:"username"`
+		RefreshToken string `json:"refreshToken" binding:"required"`
+	}
+
+	// Token Introspection
+	TokenIntrospect struct {
+		Token string `json:"token" binding:"required"`
+	}
+
+	// Token Revocation
+	TokenRevoke struct {
+		RefreshToken string `json:"refreshToken" binding:"required"`
+	}
+
+	// MFA Association
+	// Begins enrolling a TOTP authenticator app for the signed-in user.
+	MFAAssociate struct {
+		AccessToken string `json:"accessToken" binding:"required"`
+	}
+
+	// MFA Verification
+	// Confirms the TOTP code from the authenticator app enrolled via MFAAssociate.
+	MFAVerify struct {
+		AccessToken        string `json:"accessToken" binding:"required"`
+		UserCode           string `json:"userCode" binding:"required"`
+		FriendlyDeviceName string `json:"friendlyDeviceName"`
+	}
+
+	// MFA Preference
+	MFASettings struct {
+		Enabled      bool `json:"enabled"`
+		PreferredMfa bool `json:"preferredMfa"`
+	}
+	MFAPreference struct {
+		AccessToken              string       `json:"accessToken" binding:"required"`
+		SMSMfaSettings           *MFASettings `json:"smsMfaSettings"`
+		SoftwareTokenMfaSettings *MFASettings `json:"softwareTokenMfaSettings"`
+	}
+
+	// Sign-in Challenge
+	// Continues a challenge (SMS_MFA, SOFTWARE_TOKEN_MFA, NEW_PASSWORD_REQUIRED,
+	// SELECT_MFA_TYPE, ...) returned by LoginUser's ChallengeName/Session.
+	SignInChallenge struct {
+		Session            string            `json:"session" binding:"required"`
+		ChallengeName      string            `json:"challengeName" binding:"required"`
+		ChallengeResponses map[string]string `json:"challengeResponses" binding:"required"`
+	}
+
+	// OAuth2 Callback
+	// Completes the Hosted UI authorization-code flow started by GET /oauth/login.
+	OAuthCallback struct {
+		Code  string `json:"code" binding:"required"`
+		State string `json:"state" binding:"required"`
+	}
 )
 
+// oauthStateStore holds the PKCE code_verifier for each in-flight Hosted
+// UI login, keyed by the state value we handed to the browser. Entries
+// older than oauthStateTTL are treated as expired, so a stale /oauth/login
+// redirect can't be replayed indefinitely. Since GET /oauth/login requires
+// no authentication, newOAuthStateStore also starts a background janitor
+// that sweeps expired entries on its own, so logins that are abandoned or
+// retried (and never reach OAuthCallback's take()) can't grow the map
+// without bound.
+type oauthStateStore struct {
+	mu      sync.Mutex
+	entries map[string]oauthStateEntry
+}
+
+type oauthStateEntry struct {
+	codeVerifier string
+	createdAt    time.Time
+}
+
+const oauthStateTTL = 10 * time.Minute
+
+// oauthStateSweepInterval controls how often the janitor goroutine checks
+// for expired entries.
+const oauthStateSweepInterval = 1 * time.Minute
+
+func newOAuthStateStore() *oauthStateStore {
+	store := &oauthStateStore{entries: make(map[string]oauthStateEntry)}
+	go store.sweepExpired()
+	return store
+}
+
+// sweepExpired periodically removes entries older than oauthStateTTL. It
+// runs for the lifetime of the process, same as the server itself.
+func (s *oauthStateStore) sweepExpired() {
+	ticker := time.NewTicker(oauthStateSweepInterval)
+	defer ticker.Stop()
+
+	for range ticker.C {
+		s.mu.Lock()
+		for state, entry := range s.entries {
+			if time.Since(entry.createdAt) > oauthStateTTL {
+				delete(s.entries, state)
+			}
+		}
+		s.mu.Unlock()
+	}
+}
+
+func (s *oauthStateStore) put(state, codeVerifier string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.entries[state] = oauthStateEntry{codeVerifier: codeVerifier, createdAt: time.Now()}
+}
+
+// take returns and removes the code_verifier for state, so a state value
+// (and the authorization code it protects) can only be redeemed once.
+func (s *oauthStateStore) take(state string) (string, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	entry, ok := s.entries[state]
+	delete(s.entries, state)
+	if !ok || time.Since(entry.createdAt) > oauthStateTTL {
+		return "", false
+	}
+	return entry.codeVerifier, true
+}
+
 func main() {
 	// Initialize Env
 	environment.EnvInit()
 	// Setup The AWS Region and AWS session
-	conf := &aws.Config{Region: aws.String("ap-south-1")}
+	region := environment.GetAWSRegion()
+	conf := &aws.Config{Region: aws.String(region)}
 	mySession := session.Must(session.NewSession(conf))
 
 	// App instance with env
@@ -77,7 +250,15 @@ func main() {
 		UserPoolID:      environment.GetCognitoUserPoolId(),
 		AppClientID:     environment.GetCognitoAppClientId(),
 		AppClientSecret: environment.GetCognitoAppClientSecret(),
+		Region:          region,
+		Domain:          environment.GetCognitoDomain(),
+		RedirectURI:     environment.GetCognitoRedirectURI(),
 	}
+	app.JWKS = auth.NewJWKSCache(app.jwksURL())
+	app.Dispatcher = triggers.NewDispatcher()
+	app.pendingOAuth = newOAuthStateStore()
+	// Register Lambda trigger handlers here, e.g.:
+	// app.Dispatcher.OnPreSignUp(func(ctx context.Context, event *triggers.PreSignUpEvent) (*triggers.PreSignUpResponse, error) { ... })
 
 	// Gin stuff
 	r := gin.Default()
@@ -88,14 +269,108 @@ func main() {
 	})
 	r.POST("/signup", app.RegisterUser)
 	r.POST("/signup/confirmation", app.ConfirmUserRegistration)
+	r.POST("/signup/confirmation/resend", app.ResendConfirmationCode)
 	r.POST("/signin", app.LoginUser)
 	r.POST("/password/forgot", app.ForgotPassword)
 	r.POST("/password/reset", app.ResetPassword)
 	r.POST("/signout", app.LogoutUser)
+	r.GET("/me", app.RequireAuth(auth.TokenUseEither), app.Profile)
+	// Lambda triggers can have real side effects (DB writes, email sends,
+	// ban checks) once handlers are registered on app.Dispatcher, so this
+	// local test harness requires the same admin group membership as the
+	// rest of the back-office API rather than being open to the world.
+	r.POST("/triggers/test", app.RequireAuth(auth.TokenUseAccess), app.RequireGroup(adminGroupName), app.TestTrigger)
+	r.POST("/token/refresh", app.RefreshToken)
+	r.POST("/token/introspect", app.IntrospectToken)
+	r.POST("/token/revoke", app.RevokeToken)
+	r.POST("/mfa/associate", app.AssociateMFA)
+	r.POST("/mfa/verify", app.VerifyMFA)
+	r.POST("/mfa/preference", app.SetMFAPreference)
+	r.POST("/signin/challenge", app.RespondToSignInChallenge)
+	r.GET("/oauth/login", app.OAuthLogin)
+	r.POST("/oauth/callback", app.OAuthCallback)
+	app.RegisterAdminRoutes(r)
 	// Serve on 0.0.0.0:8080 or localhost:8080
 	r.Run()
 }
 
+// secretHash computes the `SECRET_HASH` Cognito requires on every call when
+// the app client has a client secret configured, as
+// base64(HMAC_SHA256(key=AppClientSecret, message=username+AppClientID)).
+// See https://docs.aws.amazon.com/cognito/latest/developerguide/signing-up-users-in-your-app.html#cognito-user-pools-computing-secret-hash
+func (app *App) secretHash(username string) string {
+	mac := hmac.New(sha256.New, []byte(app.AppClientSecret))
+	mac.Write([]byte(username + app.AppClientID))
+	return base64.StdEncoding.EncodeToString(mac.Sum(nil))
+}
+
+// issuerURL is the Cognito User Pool's issuer, used both to build the
+// JWKS endpoint and to validate the `iss` claim on incoming tokens.
+func (app *App) issuerURL() string {
+	return fmt.Sprintf("https://cognito-idp.%s.amazonaws.com/%s", app.Region, app.UserPoolID)
+}
+
+// jwksURL is the well-known JWKS endpoint for the User Pool.
+func (app *App) jwksURL() string {
+	return app.issuerURL() + "/.well-known/jwks.json"
+}
+
+// authConfig builds the auth.Config shared by RequireAuth and the
+// /token/introspect endpoint, accepting only tokens whose `token_use`
+// matches allowedTokenUse (pass auth.TokenUseEither to accept both access
+// and ID tokens).
+func (app *App) authConfig(allowedTokenUse auth.TokenUse) auth.Config {
+	return auth.Config{
+		JWKS:            app.JWKS,
+		Issuer:          app.issuerURL(),
+		AppClientID:     app.AppClientID,
+		AllowedTokenUse: allowedTokenUse,
+	}
+}
+
+// RequireAuth protects a route with the Cognito JWT middleware, accepting
+// only tokens whose `token_use` matches allowedTokenUse (pass
+// auth.TokenUseEither to accept both access and ID tokens).
+func (app *App) RequireAuth(allowedTokenUse auth.TokenUse) gin.HandlerFunc {
+	return auth.Middleware(app.authConfig(allowedTokenUse))
+}
+
+// Profile returns the caller's Cognito profile. It relies on RequireAuth
+// having already attached the token's Claims to the gin.Context.
+func (app *App) Profile(ctx *gin.Context) {
+	claims, ok := auth.ClaimsFromContext(ctx)
+	if !ok {
+		ctx.JSON(http.StatusInternalServerError, gin.H{
+			"error": "missing claims; is this route behind RequireAuth?",
+		})
+		return
+	}
+
+	user, err := app.CognitoClient.AdminGetUser(&cognito.AdminGetUserInput{
+		UserPoolId: aws.String(app.UserPoolID),
+		Username:   aws.String(claims.Username),
+	})
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+
+	attributes := gin.H{}
+	for _, attr := range user.UserAttributes {
+		attributes[*attr.Name] = *attr.Value
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{
+		"username":   claims.Username,
+		"groups":     claims.Groups,
+		"attributes": attributes,
+		"enabled":    *user.Enabled,
+		"status":     *user.UserStatus,
+	})
+}
+
 // Register
 // This struct function uses a pointer receiver i.e. "app *App" instead of "app App"
 // Because, we can only call "app.AppClientId" or "app.CognitoClient.SignUp(cognitoUser)", etc when "app" is a pointer not a value
@@ -129,6 +404,9 @@ func (app *App) RegisterUser(ctx *gin.Context) {
 			},
 		},
 	}
+	if app.AppClientSecret != "" {
+		cognitoUser.SecretHash = aws.String(app.secretHash(newUser.User.Username))
+	}
 
 	// Signup in Cognito
 	_, err := app.CognitoClient.SignUp(cognitoUser)
@@ -164,6 +442,9 @@ func (app *App) ConfirmUserRegistration(ctx *gin.Context) {
 		ConfirmationCode: aws.String(confirmUser.ConfirmationCode),
 		Username:         aws.String(confirmUser.Username),
 	}
+	if app.AppClientSecret != "" {
+		cognitoConfirm.SecretHash = aws.String(app.secretHash(confirmUser.Username))
+	}
 
 	// Confirm User Registration in Cognito
 	_, err := app.CognitoClient.ConfirmSignUp(cognitoConfirm)
@@ -180,6 +461,43 @@ func (app *App) ConfirmUserRegistration(ctx *gin.Context) {
 	})
 }
 
+// Resend User Registration Confirmation Code
+// If the original confirmation code expired or never arrived, call this to have Cognito send a new one
+func (app *App) ResendConfirmationCode(ctx *gin.Context) {
+	var resend UserResendConfirmationCode
+
+	// Validate payload
+	if err := ctx.BindJSON(&resend); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+
+	// Cognito Resend Confirmation Code input
+	cognitoResend := &cognito.ResendConfirmationCodeInput{
+		ClientId: aws.String(app.AppClientID),
+		Username: aws.String(resend.Username),
+	}
+	if app.AppClientSecret != "" {
+		cognitoResend.SecretHash = aws.String(app.secretHash(resend.Username))
+	}
+
+	// Resend Confirmation Code in Cognito
+	_, err := app.CognitoClient.ResendConfirmationCode(cognitoResend)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+
+	// OK
+	ctx.JSON(http.StatusOK, gin.H{
+		"message": "Confirmation code was resent. Please use that code to verify your account.",
+	})
+}
+
 // Login User
 func (app *App) LoginUser(ctx *gin.Context) {
 	var user User
@@ -193,16 +511,19 @@ func (app *App) LoginUser(ctx *gin.Context) {
 	}
 
 	// Cognito Signin input
+	authParameters := map[string]*string{
+		// 💡 User can send either username, email or phone number in the "username" property in payload.
+		// We have enabled it in our userpool so that users can sign in using their email address, phone number, or username
+		"USERNAME": aws.String(user.Username),
+		"PASSWORD": aws.String(user.Password),
+	}
+	if app.AppClientSecret != "" {
+		authParameters["SECRET_HASH"] = aws.String(app.secretHash(user.Username))
+	}
 	cognitoUser := &cognito.InitiateAuthInput{
-		AuthFlow: aws.String("USER_PASSWORD_AUTH"),
-		AuthParameters: map[string]*string{
-			// 💡 User can send either username, email or phone number in the "username" property in payload.
-			// We have enabled it in our userpool so that users can sign in using their email address, phone number, or username
-			"USERNAME": aws.String(user.Username),
-			"PASSWORD": aws.String(user.Password),
-			// 👆 We have not configured a Secret key for this app client so we don't need to include "SECRET_HASH" in these parameters
-		},
-		ClientId: aws.String(app.AppClientID),
+		AuthFlow:       aws.String("USER_PASSWORD_AUTH"),
+		AuthParameters: authParameters,
+		ClientId:       aws.String(app.AppClientID),
 	}
 
 	// Signin in Cognito
@@ -214,11 +535,26 @@ func (app *App) LoginUser(ctx *gin.Context) {
 		return
 	}
 
+	// 💡 On a pool with MFA (or a NEW_PASSWORD_REQUIRED policy) enabled,
+	// Cognito doesn't return tokens straight away: it returns a
+	// ChallengeName + Session instead, and expects the client to continue
+	// via POST /signin/challenge with the requested ChallengeResponses.
+	if logInResult.AuthenticationResult == nil {
+		ctx.JSON(http.StatusOK, gin.H{
+			"message":             "Additional authentication challenge required",
+			"challengeName":       *logInResult.ChallengeName,
+			"session":             *logInResult.Session,
+			"challengeParameters": logInResult.ChallengeParameters,
+		})
+		return
+	}
+
 	// OK
 	ctx.JSON(http.StatusOK, gin.H{
-		"message":     "User Logged In Successfully",
-		"accessToken": *logInResult.AuthenticationResult.AccessToken,
-		"idToken":     *logInResult.AuthenticationResult.IdToken,
+		"message":      "User Logged In Successfully",
+		"accessToken":  *logInResult.AuthenticationResult.AccessToken,
+		"idToken":      *logInResult.AuthenticationResult.IdToken,
+		"refreshToken": *logInResult.AuthenticationResult.RefreshToken,
 	})
 }
 
@@ -240,6 +576,9 @@ func (app *App) ForgotPassword(ctx *gin.Context) {
 		Username: aws.String(user.Username),
 		ClientId: aws.String(app.AppClientID),
 	}
+	if app.AppClientSecret != "" {
+		cognitoUser.SecretHash = aws.String(app.secretHash(user.Username))
+	}
 
 	// Forgot Password in Cognito
 	_, err := app.CognitoClient.ForgotPassword(cognitoUser)
@@ -276,6 +615,9 @@ func (app *App) ResetPassword(ctx *gin.Context) {
 		ConfirmationCode: aws.String(user.ConfirmationCode),
 		ClientId:         aws.String(app.AppClientID),
 	}
+	if app.AppClientSecret != "" {
+		cognitoUser.SecretHash = aws.String(app.secretHash(user.User.Username))
+	}
 
 	// Reset Password in Cognito
 	_, err := app.CognitoClient.ConfirmForgotPassword(cognitoUser)
@@ -323,3 +665,451 @@ func (app *App) LogoutUser(ctx *gin.Context) {
 		"message": "User logged out successfully.",
 	})
 }
+
+// TestTrigger runs a raw Cognito Lambda trigger payload through
+// app.Dispatcher, so trigger handlers can be exercised locally against
+// this same Gin app instead of deploying to Lambda to test them.
+func (app *App) TestTrigger(ctx *gin.Context) {
+	body, err := ctx.GetRawData()
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+
+	result, err := app.Dispatcher.Handle(ctx.Request.Context(), body)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+
+	ctx.Data(http.StatusOK, "application/json; charset=utf-8", result)
+}
+
+// RefreshToken exchanges a refresh token for a new access/ID token pair,
+// so a client doesn't need to re-prompt for credentials once its access
+// token expires.
+func (app *App) RefreshToken(ctx *gin.Context) {
+	var token TokenRefresh
+
+	// Validate payload
+	if err := ctx.BindJSON(&token); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+
+	authParameters := map[string]*string{
+		"REFRESH_TOKEN": aws.String(token.RefreshToken),
+	}
+	if app.AppClientSecret != "" {
+		if token.Username == "" {
+			ctx.JSON(http.StatusBadRequest, gin.H{
+				"error": "username is required to compute SECRET_HASH for this app client",
+			})
+			return
+		}
+		authParameters["SECRET_HASH"] = aws.String(app.secretHash(token.Username))
+	}
+
+	// Refresh Token in Cognito
+	refreshResult, err := app.CognitoClient.InitiateAuth(&cognito.InitiateAuthInput{
+		AuthFlow:       aws.String("REFRESH_TOKEN_AUTH"),
+		AuthParameters: authParameters,
+		ClientId:       aws.String(app.AppClientID),
+	})
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+
+	// OK
+	ctx.JSON(http.StatusOK, gin.H{
+		"message":     "Token refreshed successfully",
+		"accessToken": *refreshResult.AuthenticationResult.AccessToken,
+		"idToken":     *refreshResult.AuthenticationResult.IdToken,
+	})
+}
+
+// IntrospectToken locally validates a JWT against the JWKS cache and
+// reports whether it's still active, without a round-trip to Cognito.
+func (app *App) IntrospectToken(ctx *gin.Context) {
+	var token TokenIntrospect
+
+	// Validate payload
+	if err := ctx.BindJSON(&token); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+
+	claims, err := auth.VerifyToken(token.Token, app.authConfig(auth.TokenUseEither))
+	if err != nil {
+		ctx.JSON(http.StatusOK, gin.H{
+			"active": false,
+		})
+		return
+	}
+
+	// OK
+	ctx.JSON(http.StatusOK, gin.H{
+		"active":   true,
+		"sub":      claims.Sub,
+		"username": claims.Username,
+		"groups":   claims.Groups,
+		"scope":    claims.Scope,
+		"tokenUse": claims.TokenUse,
+	})
+}
+
+// RevokeToken revokes a refresh token (and every access/ID token issued
+// from it), e.g. when a user logs out of the session that refresh token
+// belongs to. Other sessions of the same user are not affected.
+func (app *App) RevokeToken(ctx *gin.Context) {
+	var token TokenRevoke
+
+	// Validate payload
+	if err := ctx.BindJSON(&token); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+
+	cognitoRevoke := &cognito.RevokeTokenInput{
+		Token:    aws.String(token.RefreshToken),
+		ClientId: aws.String(app.AppClientID),
+	}
+	if app.AppClientSecret != "" {
+		cognitoRevoke.ClientSecret = aws.String(app.AppClientSecret)
+	}
+
+	// Revoke Token in Cognito
+	_, err := app.CognitoClient.RevokeToken(cognitoRevoke)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+
+	// OK
+	ctx.JSON(http.StatusOK, gin.H{
+		"message": "Token revoked successfully",
+	})
+}
+
+// AssociateMFA begins enrolling a TOTP authenticator app for the
+// signed-in user, returning the secret the app should seed its QR code
+// with. The user confirms enrollment by calling VerifyMFA with a code
+// generated from that secret.
+func (app *App) AssociateMFA(ctx *gin.Context) {
+	var associate MFAAssociate
+
+	// Validate payload
+	if err := ctx.BindJSON(&associate); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+
+	// Associate Software Token in Cognito
+	result, err := app.CognitoClient.AssociateSoftwareToken(&cognito.AssociateSoftwareTokenInput{
+		AccessToken: aws.String(associate.AccessToken),
+	})
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+
+	// OK
+	ctx.JSON(http.StatusOK, gin.H{
+		"secretCode": *result.SecretCode,
+	})
+}
+
+// VerifyMFA confirms the TOTP code generated from the secret returned by
+// AssociateMFA, completing enrollment of the authenticator app.
+func (app *App) VerifyMFA(ctx *gin.Context) {
+	var verify MFAVerify
+
+	// Validate payload
+	if err := ctx.BindJSON(&verify); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+
+	cognitoVerify := &cognito.VerifySoftwareTokenInput{
+		AccessToken: aws.String(verify.AccessToken),
+		UserCode:    aws.String(verify.UserCode),
+	}
+	if verify.FriendlyDeviceName != "" {
+		cognitoVerify.FriendlyDeviceName = aws.String(verify.FriendlyDeviceName)
+	}
+
+	// Verify Software Token in Cognito
+	result, err := app.CognitoClient.VerifySoftwareToken(cognitoVerify)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+
+	// OK
+	ctx.JSON(http.StatusOK, gin.H{
+		"status": *result.Status,
+	})
+}
+
+// SetMFAPreference chooses which enrolled MFA method (if any) Cognito
+// should challenge the user with on future sign-ins.
+func (app *App) SetMFAPreference(ctx *gin.Context) {
+	var preference MFAPreference
+
+	// Validate payload
+	if err := ctx.BindJSON(&preference); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+
+	cognitoPreference := &cognito.SetUserMFAPreferenceInput{
+		AccessToken: aws.String(preference.AccessToken),
+	}
+	if preference.SMSMfaSettings != nil {
+		cognitoPreference.SMSMfaSettings = &cognito.SMSMfaSettingsType{
+			Enabled:      aws.Bool(preference.SMSMfaSettings.Enabled),
+			PreferredMfa: aws.Bool(preference.SMSMfaSettings.PreferredMfa),
+		}
+	}
+	if preference.SoftwareTokenMfaSettings != nil {
+		cognitoPreference.SoftwareTokenMfaSettings = &cognito.SoftwareTokenMfaSettingsType{
+			Enabled:      aws.Bool(preference.SoftwareTokenMfaSettings.Enabled),
+			PreferredMfa: aws.Bool(preference.SoftwareTokenMfaSettings.PreferredMfa),
+		}
+	}
+
+	// Set MFA Preference in Cognito
+	_, err := app.CognitoClient.SetUserMFAPreference(cognitoPreference)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+
+	// OK
+	ctx.JSON(http.StatusOK, gin.H{
+		"message": "MFA preference updated successfully",
+	})
+}
+
+// RespondToSignInChallenge continues the challenge protocol LoginUser
+// starts when a pool has MFA or a NEW_PASSWORD_REQUIRED policy enabled:
+// SMS_MFA, SOFTWARE_TOKEN_MFA, NEW_PASSWORD_REQUIRED, and SELECT_MFA_TYPE.
+func (app *App) RespondToSignInChallenge(ctx *gin.Context) {
+	var challenge SignInChallenge
+
+	// Validate payload
+	if err := ctx.BindJSON(&challenge); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+
+	challengeResponses := make(map[string]*string, len(challenge.ChallengeResponses))
+	for key, value := range challenge.ChallengeResponses {
+		challengeResponses[key] = aws.String(value)
+	}
+	// 💡 Cognito expects SECRET_HASH alongside the challenge's own
+	// responses, keyed off the USERNAME the client included.
+	if app.AppClientSecret != "" {
+		if username, ok := challenge.ChallengeResponses["USERNAME"]; ok {
+			challengeResponses["SECRET_HASH"] = aws.String(app.secretHash(username))
+		}
+	}
+
+	// Respond to Auth Challenge in Cognito
+	result, err := app.CognitoClient.RespondToAuthChallenge(&cognito.RespondToAuthChallengeInput{
+		ChallengeName:      aws.String(challenge.ChallengeName),
+		ClientId:           aws.String(app.AppClientID),
+		Session:            aws.String(challenge.Session),
+		ChallengeResponses: challengeResponses,
+	})
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+
+	// 💡 A challenge can itself be followed by another challenge (e.g.
+	// NEW_PASSWORD_REQUIRED then SOFTWARE_TOKEN_MFA), so we surface the
+	// same shape LoginUser does rather than assuming tokens are ready.
+	if result.AuthenticationResult == nil {
+		ctx.JSON(http.StatusOK, gin.H{
+			"message":             "Additional authentication challenge required",
+			"challengeName":       *result.ChallengeName,
+			"session":             *result.Session,
+			"challengeParameters": result.ChallengeParameters,
+		})
+		return
+	}
+
+	// OK
+	ctx.JSON(http.StatusOK, gin.H{
+		"message":      "User Logged In Successfully",
+		"accessToken":  *result.AuthenticationResult.AccessToken,
+		"idToken":      *result.AuthenticationResult.IdToken,
+		"refreshToken": *result.AuthenticationResult.RefreshToken,
+	})
+}
+
+// OAuthLogin redirects the browser to the Hosted UI's authorization
+// endpoint, kicking off the OAuth2 authorization-code flow (optionally
+// straight to a federated IdP via the ?identityProvider= query param, e.g.
+// "Google", "Facebook", "SignInWithApple", or a configured SAML provider).
+func (app *App) OAuthLogin(ctx *gin.Context) {
+	state, err := randomURLSafeString(32)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+	codeVerifier, err := randomURLSafeString(64)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+	app.pendingOAuth.put(state, codeVerifier)
+
+	query := url.Values{
+		"response_type":         {"code"},
+		"client_id":             {app.AppClientID},
+		"redirect_uri":          {app.RedirectURI},
+		"scope":                 {"openid email profile"},
+		"state":                 {state},
+		"code_challenge":        {pkceCodeChallenge(codeVerifier)},
+		"code_challenge_method": {"S256"},
+	}
+	if identityProvider := ctx.Query("identityProvider"); identityProvider != "" {
+		query.Set("identity_provider", identityProvider)
+	}
+
+	authorizeURL := fmt.Sprintf("https://%s/oauth2/authorize?%s", app.Domain, query.Encode())
+	ctx.Redirect(http.StatusFound, authorizeURL)
+}
+
+// OAuthCallback exchanges the authorization code Cognito's Hosted UI
+// redirected back with for tokens, after verifying the state and PKCE
+// code_verifier generated in OAuthLogin.
+func (app *App) OAuthCallback(ctx *gin.Context) {
+	var callback OAuthCallback
+
+	// Validate payload
+	if err := ctx.BindJSON(&callback); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+
+	codeVerifier, ok := app.pendingOAuth.take(callback.State)
+	if !ok {
+		ctx.JSON(http.StatusBadRequest, gin.H{
+			"error": "unknown or expired state",
+		})
+		return
+	}
+
+	form := url.Values{
+		"grant_type":    {"authorization_code"},
+		"client_id":     {app.AppClientID},
+		"code":          {callback.Code},
+		"redirect_uri":  {app.RedirectURI},
+		"code_verifier": {codeVerifier},
+	}
+	if app.AppClientSecret != "" {
+		form.Set("client_secret", app.AppClientSecret)
+	}
+
+	tokenURL := fmt.Sprintf("https://%s/oauth2/token", app.Domain)
+	resp, err := http.Post(tokenURL, "application/x-www-form-urlencoded", strings.NewReader(form.Encode()))
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		ctx.JSON(http.StatusBadGateway, gin.H{
+			"error": fmt.Sprintf("token exchange failed: %s", body),
+		})
+		return
+	}
+
+	var tokens struct {
+		AccessToken  string `json:"access_token"`
+		IDToken      string `json:"id_token"`
+		RefreshToken string `json:"refresh_token"`
+	}
+	if err := json.Unmarshal(body, &tokens); err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+
+	// OK
+	ctx.JSON(http.StatusOK, gin.H{
+		"message":      "User Logged In Successfully",
+		"accessToken":  tokens.AccessToken,
+		"idToken":      tokens.IDToken,
+		"refreshToken": tokens.RefreshToken,
+	})
+}
+
+// randomURLSafeString returns a base64url-encoded random string built from
+// n random bytes, suitable for an OAuth2 state value or PKCE code_verifier.
+func randomURLSafeString(n int) (string, error) {
+	b := make([]byte, n)
+	if _, err := rand.Read(b); err != nil {
+		return "", fmt.Errorf("generating random string: %w", err)
+	}
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
+
+// pkceCodeChallenge derives the PKCE code_challenge (S256 method) from a
+// code_verifier, per RFC 7636.
+func pkceCodeChallenge(codeVerifier string) string {
+	sum := sha256.Sum256([]byte(codeVerifier))
+	return base64.RawURLEncoding.EncodeToString(sum[:])
+}
@@ -0,0 +1,138 @@
+package triggers
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+// Dispatcher routes a raw Cognito Lambda trigger payload to the handler
+// registered for its triggerSource, and marshals the handler's response
+// back into the shape Cognito expects (the original event, with Response
+// populated). A Dispatcher with no handlers registered returns every
+// recognized event with its Response as received, matching Cognito's own
+// "do nothing" behaviour when a trigger isn't customized. The event is
+// re-encoded from the structs in events.go, so fields they do not model
+// are dropped.
+type Dispatcher struct {
+	onPreSignUp                   func(ctx context.Context, event *PreSignUpEvent) (*PreSignUpResponse, error)
+	onPostConfirmation            func(ctx context.Context, event *PostConfirmationEvent) (*PostConfirmationResponse, error)
+	onPreAuthentication           func(ctx context.Context, event *PreAuthenticationEvent) (*PreAuthenticationResponse, error)
+	onPostAuthentication          func(ctx context.Context, event *PostAuthenticationEvent) (*PostAuthenticationResponse, error)
+	onCustomMessage               func(ctx context.Context, event *CustomMessageEvent) (*CustomMessageResponse, error)
+	onPreTokenGeneration          func(ctx context.Context, event *PreTokenGenerationEvent) (*PreTokenGenerationResponse, error)
+	onDefineAuthChallenge         func(ctx context.Context, event *DefineAuthChallengeEvent) (*DefineAuthChallengeResponse, error)
+	onCreateAuthChallenge         func(ctx context.Context, event *CreateAuthChallengeEvent) (*CreateAuthChallengeResponse, error)
+	onVerifyAuthChallengeResponse func(ctx context.Context, event *VerifyAuthChallengeResponseEvent) (*VerifyAuthChallengeResponseResponse, error)
+	onUserMigration               func(ctx context.Context, event *UserMigrationEvent) (*UserMigrationResponse, error)
+}
+
+// NewDispatcher returns an empty Dispatcher. Register handlers with the
+// OnXxx methods before wiring Handle up to your Lambda entry point (or to
+// the local HTTP test harness).
+func NewDispatcher() *Dispatcher {
+	return &Dispatcher{}
+}
+
+func (d *Dispatcher) OnPreSignUp(handler func(ctx context.Context, event *PreSignUpEvent) (*PreSignUpResponse, error)) {
+	d.onPreSignUp = handler
+}
+
+func (d *Dispatcher) OnPostConfirmation(handler func(ctx context.Context, event *PostConfirmationEvent) (*PostConfirmationResponse, error)) {
+	d.onPostConfirmation = handler
+}
+
+func (d *Dispatcher) OnPreAuthentication(handler func(ctx context.Context, event *PreAuthenticationEvent) (*PreAuthenticationResponse, error)) {
+	d.onPreAuthentication = handler
+}
+
+func (d *Dispatcher) OnPostAuthentication(handler func(ctx context.Context, event *PostAuthenticationEvent) (*PostAuthenticationResponse, error)) {
+	d.onPostAuthentication = handler
+}
+
+func (d *Dispatcher) OnCustomMessage(handler func(ctx context.Context, event *CustomMessageEvent) (*CustomMessageResponse, error)) {
+	d.onCustomMessage = handler
+}
+
+func (d *Dispatcher) OnPreTokenGeneration(handler func(ctx context.Context, event *PreTokenGenerationEvent) (*PreTokenGenerationResponse, error)) {
+	d.onPreTokenGeneration = handler
+}
+
+func (d *Dispatcher) OnDefineAuthChallenge(handler func(ctx context.Context, event *DefineAuthChallengeEvent) (*DefineAuthChallengeResponse, error)) {
+	d.onDefineAuthChallenge = handler
+}
+
+func (d *Dispatcher) OnCreateAuthChallenge(handler func(ctx context.Context, event *CreateAuthChallengeEvent) (*CreateAuthChallengeResponse, error)) {
+	d.onCreateAuthChallenge = handler
+}
+
+func (d *Dispatcher) OnVerifyAuthChallengeResponse(handler func(ctx context.Context, event *VerifyAuthChallengeResponseEvent) (*VerifyAuthChallengeResponseResponse, error)) {
+	d.onVerifyAuthChallengeResponse = handler
+}
+
+func (d *Dispatcher) OnUserMigration(handler func(ctx context.Context, event *UserMigrationEvent) (*UserMigrationResponse, error)) {
+	d.onUserMigration = handler
+}
+
+// Handle is the single entry point for both the Lambda runtime (via
+// aws-lambda-go) and the local HTTP test harness: it inspects
+// triggerSource, dispatches to the matching registered handler, and
+// returns the event with its Response populated, re-marshaled to JSON.
+func (d *Dispatcher) Handle(ctx context.Context, raw json.RawMessage) (json.RawMessage, error) {
+	var probe struct {
+		TriggerSource string `json:"triggerSource"`
+	}
+	if err := json.Unmarshal(raw, &probe); err != nil {
+		return nil, fmt.Errorf("triggers: decoding envelope: %w", err)
+	}
+
+	switch {
+	case strings.HasPrefix(probe.TriggerSource, sourcePreSignUp):
+		return dispatch(raw, d.onPreSignUp, func(e *PreSignUpEvent, r *PreSignUpResponse) { e.Response = *r })(ctx)
+	case strings.HasPrefix(probe.TriggerSource, sourcePostConfirmation):
+		return dispatch(raw, d.onPostConfirmation, func(e *PostConfirmationEvent, r *PostConfirmationResponse) { e.Response = *r })(ctx)
+	case strings.HasPrefix(probe.TriggerSource, sourcePreAuthentication):
+		return dispatch(raw, d.onPreAuthentication, func(e *PreAuthenticationEvent, r *PreAuthenticationResponse) { e.Response = *r })(ctx)
+	case strings.HasPrefix(probe.TriggerSource, sourcePostAuthentication):
+		return dispatch(raw, d.onPostAuthentication, func(e *PostAuthenticationEvent, r *PostAuthenticationResponse) { e.Response = *r })(ctx)
+	case strings.HasPrefix(probe.TriggerSource, sourceCustomMessage):
+		return dispatch(raw, d.onCustomMessage, func(e *CustomMessageEvent, r *CustomMessageResponse) { e.Response = *r })(ctx)
+	case strings.HasPrefix(probe.TriggerSource, sourcePreTokenGeneration):
+		return dispatch(raw, d.onPreTokenGeneration, func(e *PreTokenGenerationEvent, r *PreTokenGenerationResponse) { e.Response = *r })(ctx)
+	case strings.HasPrefix(probe.TriggerSource, sourceDefineAuthChallenge):
+		return dispatch(raw, d.onDefineAuthChallenge, func(e *DefineAuthChallengeEvent, r *DefineAuthChallengeResponse) { e.Response = *r })(ctx)
+	case strings.HasPrefix(probe.TriggerSource, sourceCreateAuthChallenge):
+		return dispatch(raw, d.onCreateAuthChallenge, func(e *CreateAuthChallengeEvent, r *CreateAuthChallengeResponse) { e.Response = *r })(ctx)
+	case strings.HasPrefix(probe.TriggerSource, sourceVerifyAuthChallengeResponse):
+		return dispatch(raw, d.onVerifyAuthChallengeResponse, func(e *VerifyAuthChallengeResponseEvent, r *VerifyAuthChallengeResponseResponse) { e.Response = *r })(ctx)
+	case strings.HasPrefix(probe.TriggerSource, sourceUserMigration):
+		return dispatch(raw, d.onUserMigration, func(e *UserMigrationEvent, r *UserMigrationResponse) { e.Response = *r })(ctx)
+	default:
+		return nil, fmt.Errorf("triggers: unrecognized triggerSource %q", probe.TriggerSource)
+	}
+}
+
+// dispatch decodes raw into *E, invokes handler if registered, applies its
+// response onto the event via apply, and re-marshals the event. If handler
+// is nil the event is re-marshaled with its Response as received, which
+// mirrors Cognito's default behaviour for a trigger that has no Lambda
+// attached.
+func dispatch[E any, R any](raw json.RawMessage, handler func(ctx context.Context, event *E) (*R, error), apply func(*E, *R)) func(ctx context.Context) (json.RawMessage, error) {
+	return func(ctx context.Context) (json.RawMessage, error) {
+		var event E
+		if err := json.Unmarshal(raw, &event); err != nil {
+			return nil, fmt.Errorf("triggers: decoding event: %w", err)
+		}
+
+		if handler != nil {
+			response, err := handler(ctx, &event)
+			if err != nil {
+				return nil, err
+			}
+			apply(&event, response)
+		}
+
+		return json.Marshal(event)
+	}
+}
@@ -0,0 +1,246 @@
+// Package triggers models the JSON event payloads Cognito sends to a User
+// Pool Lambda trigger, and dispatches them to typed handlers. The structs
+// here mirror the shared envelope Cognito wraps every trigger in (version,
+// triggerSource, region, userPoolId, userName, callerContext) plus the
+// request/response shape specific to each trigger.
+//
+// See https://docs.aws.amazon.com/cognito/latest/developerguide/cognito-user-identity-pools-working-with-aws-lambda-triggers.html
+package triggers
+
+// Trigger source prefixes as sent in the `triggerSource` field. Some
+// triggers (CustomMessage, PreTokenGeneration) fire for several distinct
+// sources depending on which flow invoked them, so we match on prefix
+// rather than an exact value.
+const (
+	sourcePreSignUp                   = "PreSignUp_"
+	sourcePostConfirmation            = "PostConfirmation_"
+	sourcePreAuthentication           = "PreAuthentication_"
+	sourcePostAuthentication          = "PostAuthentication_"
+	sourceCustomMessage               = "CustomMessage_"
+	sourcePreTokenGeneration          = "TokenGeneration_"
+	sourceDefineAuthChallenge         = "DefineAuthChallenge_"
+	sourceCreateAuthChallenge         = "CreateAuthChallenge_"
+	sourceVerifyAuthChallengeResponse = "VerifyAuthChallengeResponse_"
+	sourceUserMigration               = "UserMigration_"
+)
+
+// CallerContext identifies the client that triggered the Lambda.
+type CallerContext struct {
+	AWSSDKVersion string `json:"awsSdkVersion"`
+	ClientID      string `json:"clientId"`
+}
+
+// envelope carries the fields shared by every trigger event. It is embedded
+// anonymously in each typed event below so its fields are promoted and
+// flatten into the same JSON object as that event's own Request/Response,
+// matching the single flat payload Cognito actually sends.
+type envelope struct {
+	Version       string        `json:"version"`
+	TriggerSource string        `json:"triggerSource"`
+	Region        string        `json:"region"`
+	UserPoolID    string        `json:"userPoolId"`
+	UserName      string        `json:"userName"`
+	CallerContext CallerContext `json:"callerContext"`
+}
+
+// PreSignUpEvent fires before a user is created, letting the trigger
+// auto-confirm or auto-verify the user.
+type PreSignUpEvent struct {
+	envelope
+	Request  PreSignUpRequest  `json:"request"`
+	Response PreSignUpResponse `json:"response"`
+}
+
+type PreSignUpRequest struct {
+	UserAttributes map[string]string `json:"userAttributes"`
+	ValidationData map[string]string `json:"validationData"`
+	ClientMetadata map[string]string `json:"clientMetadata"`
+}
+
+type PreSignUpResponse struct {
+	AutoConfirmUser bool `json:"autoConfirmUser"`
+	AutoVerifyEmail bool `json:"autoVerifyEmail"`
+	AutoVerifyPhone bool `json:"autoVerifyPhone"`
+}
+
+// PostConfirmationEvent fires after a user confirms their account, or after
+// an admin confirms one. Typically used to provision downstream resources.
+type PostConfirmationEvent struct {
+	envelope
+	Request  PostConfirmationRequest  `json:"request"`
+	Response PostConfirmationResponse `json:"response"`
+}
+
+type PostConfirmationRequest struct {
+	UserAttributes map[string]string `json:"userAttributes"`
+	ClientMetadata map[string]string `json:"clientMetadata"`
+}
+
+type PostConfirmationResponse struct{}
+
+// PreAuthenticationEvent fires before a user is allowed to authenticate,
+// letting the trigger deny sign-in (e.g. for a custom ban list) by
+// returning an error from the registered handler.
+type PreAuthenticationEvent struct {
+	envelope
+	Request  PreAuthenticationRequest  `json:"request"`
+	Response PreAuthenticationResponse `json:"response"`
+}
+
+type PreAuthenticationRequest struct {
+	UserAttributes map[string]string `json:"userAttributes"`
+	ValidationData map[string]string `json:"validationData"`
+}
+
+type PreAuthenticationResponse struct{}
+
+// PostAuthenticationEvent fires after a user successfully authenticates,
+// e.g. to record a last-login timestamp.
+type PostAuthenticationEvent struct {
+	envelope
+	Request  PostAuthenticationRequest  `json:"request"`
+	Response PostAuthenticationResponse `json:"response"`
+}
+
+type PostAuthenticationRequest struct {
+	UserAttributes map[string]string `json:"userAttributes"`
+	NewDeviceUsed  bool              `json:"newDeviceUsed"`
+}
+
+type PostAuthenticationResponse struct{}
+
+// CustomMessageEvent fires before Cognito sends an email/SMS, letting the
+// trigger override the message body.
+type CustomMessageEvent struct {
+	envelope
+	Request  CustomMessageRequest  `json:"request"`
+	Response CustomMessageResponse `json:"response"`
+}
+
+type CustomMessageRequest struct {
+	UserAttributes    map[string]string `json:"userAttributes"`
+	CodeParameter     string            `json:"codeParameter"`
+	UsernameParameter string            `json:"usernameParameter"`
+	ClientMetadata    map[string]string `json:"clientMetadata"`
+}
+
+type CustomMessageResponse struct {
+	SMSMessage   string `json:"smsMessage"`
+	EmailMessage string `json:"emailMessage"`
+	EmailSubject string `json:"emailSubject"`
+}
+
+// PreTokenGenerationEvent fires before tokens are issued, letting the
+// trigger add, suppress, or override claims.
+type PreTokenGenerationEvent struct {
+	envelope
+	Request  PreTokenGenerationRequest  `json:"request"`
+	Response PreTokenGenerationResponse `json:"response"`
+}
+
+type PreTokenGenerationRequest struct {
+	UserAttributes     map[string]string `json:"userAttributes"`
+	GroupConfiguration struct {
+		GroupsToOverride   []string `json:"groupsToOverride"`
+		IAMRolesToOverride []string `json:"iamRolesToOverride"`
+		PreferredRole      string   `json:"preferredRole"`
+	} `json:"groupConfiguration"`
+	ClientMetadata map[string]string `json:"clientMetadata"`
+}
+
+type PreTokenGenerationResponse struct {
+	ClaimsOverrideDetails struct {
+		ClaimsToAddOrOverride map[string]string `json:"claimsToAddOrOverride"`
+		ClaimsToSuppress      []string          `json:"claimsToSuppress"`
+	} `json:"claimsOverrideDetails"`
+}
+
+// DefineAuthChallengeEvent fires during a custom authentication flow to
+// decide what challenge (if any) to issue next.
+type DefineAuthChallengeEvent struct {
+	envelope
+	Request  DefineAuthChallengeRequest  `json:"request"`
+	Response DefineAuthChallengeResponse `json:"response"`
+}
+
+type ChallengeResult struct {
+	ChallengeName     string `json:"challengeName"`
+	ChallengeResult   bool   `json:"challengeResult"`
+	ChallengeMetadata string `json:"challengeMetadata"`
+}
+
+type DefineAuthChallengeRequest struct {
+	UserAttributes map[string]string `json:"userAttributes"`
+	Session        []ChallengeResult `json:"session"`
+	ClientMetadata map[string]string `json:"clientMetadata"`
+	UserNotFound   bool              `json:"userNotFound"`
+}
+
+type DefineAuthChallengeResponse struct {
+	ChallengeName      string `json:"challengeName"`
+	IssueTokens        bool   `json:"issueTokens"`
+	FailAuthentication bool   `json:"failAuthentication"`
+}
+
+// CreateAuthChallengeEvent fires to build the challenge defined by
+// DefineAuthChallenge (e.g. generate and deliver an OTP).
+type CreateAuthChallengeEvent struct {
+	envelope
+	Request  CreateAuthChallengeRequest  `json:"request"`
+	Response CreateAuthChallengeResponse `json:"response"`
+}
+
+type CreateAuthChallengeRequest struct {
+	UserAttributes map[string]string `json:"userAttributes"`
+	ChallengeName  string            `json:"challengeName"`
+	Session        []ChallengeResult `json:"session"`
+	ClientMetadata map[string]string `json:"clientMetadata"`
+}
+
+type CreateAuthChallengeResponse struct {
+	PublicChallengeParameters  map[string]string `json:"publicChallengeParameters"`
+	PrivateChallengeParameters map[string]string `json:"privateChallengeParameters"`
+	ChallengeMetadata          string            `json:"challengeMetadata"`
+}
+
+// VerifyAuthChallengeResponseEvent fires to check the user's answer to the
+// challenge created by CreateAuthChallenge.
+type VerifyAuthChallengeResponseEvent struct {
+	envelope
+	Request  VerifyAuthChallengeResponseRequest  `json:"request"`
+	Response VerifyAuthChallengeResponseResponse `json:"response"`
+}
+
+type VerifyAuthChallengeResponseRequest struct {
+	UserAttributes             map[string]string `json:"userAttributes"`
+	PrivateChallengeParameters map[string]string `json:"privateChallengeParameters"`
+	ChallengeAnswer            string            `json:"challengeAnswer"`
+	ClientMetadata             map[string]string `json:"clientMetadata"`
+}
+
+type VerifyAuthChallengeResponseResponse struct {
+	AnswerCorrect bool `json:"answerCorrect"`
+}
+
+// UserMigrationEvent fires when a user that doesn't yet exist in the pool
+// signs in or resets their password, letting the trigger migrate them from
+// a legacy user store.
+type UserMigrationEvent struct {
+	envelope
+	Request  UserMigrationRequest  `json:"request"`
+	Response UserMigrationResponse `json:"response"`
+}
+
+type UserMigrationRequest struct {
+	Password       string            `json:"password"`
+	ValidationData map[string]string `json:"validationData"`
+	ClientMetadata map[string]string `json:"clientMetadata"`
+}
+
+type UserMigrationResponse struct {
+	UserAttributes         map[string]string `json:"userAttributes"`
+	FinalUserStatus        string            `json:"finalUserStatus"`
+	MessageAction          string            `json:"messageAction"`
+	DesiredDeliveryMediums []string          `json:"desiredDeliveryMediums"`
+	ForceAliasCreation     bool              `json:"forceAliasCreation"`
+}